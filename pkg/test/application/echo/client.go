@@ -16,21 +16,30 @@ package echo
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 
 	"istio.io/istio/pkg/test/application/echo/proto"
 )
 
 const (
 	codeOK = "200"
+
+	// headerRequestID is the canonical header name under which the
+	// structured EchoReply carries the value previously scraped out of the
+	// legacy text blob via idRegex.
+	headerRequestID = "X-Request-Id"
 )
 
 var (
@@ -48,10 +57,74 @@ type Client struct {
 	client proto.EchoTestServiceClient
 }
 
-// NewClient creates a new EchoClient instance that is connected to the given address.
+// ClientOptions customizes how a Client dials the echo command endpoint.
+// The zero value dials insecurely with no timeout, matching the historical
+// behavior of NewClient.
+type ClientOptions struct {
+	// TLSConfig, if set, is used to build transport credentials for the
+	// dial. Ignored if TransportCredentials is also set.
+	TLSConfig *tls.Config
+	// TransportCredentials, if set, takes precedence over TLSConfig and
+	// allows callers to supply arbitrary credentials (e.g. mutual TLS with
+	// a custom verifier, or ALTS).
+	TransportCredentials credentials.TransportCredentials
+	// DialTimeout bounds how long Dial may block. Zero means no timeout.
+	DialTimeout time.Duration
+	// KeepaliveParams configures gRPC keepalive pings on the connection.
+	KeepaliveParams *keepalive.ClientParameters
+	// DialOptions are appended after the options derived from the fields
+	// above, so callers can override or extend the defaults.
+	DialOptions []grpc.DialOption
+	// UnaryInterceptors are installed in addition to any supplied via
+	// DialOptions.
+	UnaryInterceptors []grpc.UnaryClientInterceptor
+	// StreamInterceptors are installed in addition to any supplied via
+	// DialOptions.
+	StreamInterceptors []grpc.StreamClientInterceptor
+}
+
+// NewClient creates a new EchoClient instance that is connected to the given
+// address using an insecure connection. For TLS, mutual TLS, or other dial
+// customization, use NewClientWithOptions.
 func NewClient(address string) (*Client, error) {
+	return NewClientWithOptions(address, ClientOptions{})
+}
+
+// NewClientWithOptions creates a new EchoClient instance connected to the
+// given address, dialing according to the given ClientOptions.
+func NewClientWithOptions(address string, opts ClientOptions) (*Client, error) {
+	dialOptions := make([]grpc.DialOption, 0, len(opts.DialOptions)+4)
+
+	switch {
+	case opts.TransportCredentials != nil:
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(opts.TransportCredentials))
+	case opts.TLSConfig != nil:
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(opts.TLSConfig)))
+	default:
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	}
+
+	if opts.KeepaliveParams != nil {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(*opts.KeepaliveParams))
+	}
+	if len(opts.UnaryInterceptors) > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(opts.UnaryInterceptors...))
+	}
+	if len(opts.StreamInterceptors) > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainStreamInterceptor(opts.StreamInterceptors...))
+	}
+	dialOptions = append(dialOptions, opts.DialOptions...)
+
+	ctx := context.Background()
+	if opts.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.DialTimeout)
+		defer cancel()
+		dialOptions = append(dialOptions, grpc.WithBlock())
+	}
+
 	// Connect to the GRPC (command) endpoint of 'this' app.
-	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	conn, err := grpc.DialContext(ctx, address, dialOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -72,9 +145,10 @@ func (c *Client) Close() error {
 }
 
 // ForwardEcho sends the given forward request and parses the response for easier processing. Only fails if the request fails.
-func (c *Client) ForwardEcho(request *proto.ForwardEchoRequest) (ParsedResponses, error) {
+// The given context governs the lifetime of the call, allowing callers to apply deadlines and cancellation.
+func (c *Client) ForwardEcho(ctx context.Context, request *proto.ForwardEchoRequest) (ParsedResponses, error) {
 	// Forward a request from 'this' service to the destination service.
-	resp, err := c.client.ForwardEcho(context.Background(), request)
+	resp, err := c.client.ForwardEcho(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +172,32 @@ type ParsedResponse struct {
 	Host string
 	// Hostname is the host that responded to the request
 	Hostname string
+	// Headers holds the full set of response headers. Only populated when
+	// the server returns a structured EchoReply; nil for legacy servers.
+	Headers map[string]string
+	// Trailers holds any HTTP/2 trailers returned with the response. Only
+	// populated when the server returns a structured EchoReply.
+	Trailers map[string]string
+	// ResponseTime is the server-observed round trip time of the call. Only
+	// populated when the server returns a structured EchoReply.
+	ResponseTime time.Duration
+	// TLSVersion is the negotiated TLS protocol version, e.g. "TLSv1.3".
+	// Only populated when the server returns a structured EchoReply.
+	TLSVersion string
+	// TLSPeerCertSubject is the subject of the peer certificate presented
+	// during the TLS handshake, if any.
+	TLSPeerCertSubject string
+	// HTTP2StreamID is the HTTP/2 stream the response was sent on, if
+	// applicable.
+	HTTP2StreamID int32
+	// NegotiatedProtocol is the protocol negotiated via ALPN during the TLS
+	// handshake, e.g. "h2". Only populated when the server returns a
+	// structured EchoReply.
+	NegotiatedProtocol string
+	// UpgradeHeader is the value of the response `Upgrade` header, e.g.
+	// "websocket", if the connection was upgraded. Only populated when the
+	// server returns a structured EchoReply.
+	UpgradeHeader string
 }
 
 // IsOK indicates whether or not the code indicates a successful request.
@@ -183,6 +283,88 @@ func (r ParsedResponses) CheckPortOrFail(t testing.TB, expected int) {
 	}
 }
 
+// CheckHeader asserts that every response carries the given header with the
+// expected value. Requires the server to have returned a structured
+// EchoReply; servers that only emit the legacy text blob do not populate
+// response headers. No in-tree echo server emits EchoReply yet (see
+// proto.ForwardEchoResponse.OutputReply), so this check is unreachable
+// against a real server until that server-side work lands.
+func (r ParsedResponses) CheckHeader(key, expected string) error {
+	return r.Check(func(i int, response *ParsedResponse) error {
+		if response.Headers == nil {
+			return fmt.Errorf("response[%d] has no structured headers to check", i)
+		}
+		if actual := response.Headers[key]; actual != expected {
+			return fmt.Errorf("response[%d] header %s: expected %s, received %s", i, key, expected, actual)
+		}
+		return nil
+	})
+}
+
+func (r ParsedResponses) CheckHeaderOrFail(t testing.TB, key, expected string) {
+	if err := r.CheckHeader(key, expected); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// CheckTLSVersion asserts that every response was served over the expected
+// negotiated TLS protocol version, e.g. "TLSv1.3". Like CheckHeader, this
+// depends on structured EchoReply data that no in-tree server populates yet.
+func (r ParsedResponses) CheckTLSVersion(expected string) error {
+	return r.Check(func(i int, response *ParsedResponse) error {
+		if response.TLSVersion != expected {
+			return fmt.Errorf("response[%d] TLS version: expected %s, received %s", i, expected, response.TLSVersion)
+		}
+		return nil
+	})
+}
+
+func (r ParsedResponses) CheckTLSVersionOrFail(t testing.TB, expected string) {
+	if err := r.CheckTLSVersion(expected); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// CheckProtocol asserts that every response negotiated the expected
+// application protocol via ALPN, e.g. "h2". Depends on structured EchoReply
+// data that no in-tree server populates yet (see the scoping note on
+// proto.Protocol), so NegotiatedProtocol is always empty against a real
+// server until that server-side work lands.
+func (r ParsedResponses) CheckProtocol(expected string) error {
+	return r.Check(func(i int, response *ParsedResponse) error {
+		if response.NegotiatedProtocol != expected {
+			return fmt.Errorf("response[%d] negotiated protocol: expected %s, received %s",
+				i, expected, response.NegotiatedProtocol)
+		}
+		return nil
+	})
+}
+
+func (r ParsedResponses) CheckProtocolOrFail(t testing.TB, expected string) {
+	if err := r.CheckProtocol(expected); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// CheckUpgraded asserts that every response carried the given `Upgrade`
+// header value, e.g. "websocket". Like CheckProtocol, this is unreachable
+// against a real server until the server-side forwarder work lands.
+func (r ParsedResponses) CheckUpgraded(expected string) error {
+	return r.Check(func(i int, response *ParsedResponse) error {
+		if response.UpgradeHeader != expected {
+			return fmt.Errorf("response[%d] upgrade header: expected %s, received %s",
+				i, expected, response.UpgradeHeader)
+		}
+		return nil
+	})
+}
+
+func (r ParsedResponses) CheckUpgradedOrFail(t testing.TB, expected string) {
+	if err := r.CheckUpgraded(expected); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // Count occurrences of the given text within the bodies of all responses.
 func (r ParsedResponses) Count(text string) int {
 	count := 0
@@ -193,6 +375,20 @@ func (r ParsedResponses) Count(text string) int {
 }
 
 func parseForwardedResponse(resp *proto.ForwardEchoResponse) ParsedResponses {
+	// Prefer the structured replies when the server populated them. Older
+	// servers only fill in the legacy `Output` text blob, so fall back to
+	// regex parsing in that case. As of this writing no in-tree echo server
+	// populates OutputReply (server-side emission is a tracked follow-up),
+	// so this branch is client/proto scaffolding and the regex path below
+	// is always the one exercised against a real server.
+	if len(resp.OutputReply) > 0 {
+		responses := make([]*ParsedResponse, len(resp.OutputReply))
+		for i, reply := range resp.OutputReply {
+			responses[i] = parseEchoReply(reply)
+		}
+		return responses
+	}
+
 	responses := make([]*ParsedResponse, len(resp.Output))
 	for i, output := range resp.Output {
 		responses[i] = parseResponse(output)
@@ -200,6 +396,28 @@ func parseForwardedResponse(resp *proto.ForwardEchoResponse) ParsedResponses {
 	return responses
 }
 
+// parseEchoReply converts a structured EchoReply from the server into a
+// ParsedResponse, without resorting to regex scraping of a text blob.
+func parseEchoReply(reply *proto.EchoReply) *ParsedResponse {
+	return &ParsedResponse{
+		Body:               reply.Body,
+		ID:                 reply.Headers[headerRequestID],
+		Version:            reply.ServiceVersion,
+		Port:               reply.ServicePort,
+		Code:               strconv.Itoa(int(reply.StatusCode)),
+		Host:               reply.Host,
+		Hostname:           reply.Hostname,
+		Headers:            reply.Headers,
+		Trailers:           reply.Trailers,
+		ResponseTime:       time.Duration(reply.ResponseTimeMicros) * time.Microsecond,
+		TLSVersion:         reply.TlsVersion,
+		TLSPeerCertSubject: reply.TlsPeerCertSubject,
+		HTTP2StreamID:      reply.Http2StreamId,
+		NegotiatedProtocol: reply.AlpnProtocol,
+		UpgradeHeader:      reply.UpgradeHeader,
+	}
+}
+
 func parseResponse(output string) *ParsedResponse {
 	out := ParsedResponse{
 		Body: output,
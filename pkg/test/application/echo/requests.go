@@ -0,0 +1,51 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package echo
+
+import (
+	"istio.io/istio/pkg/test/application/echo/proto"
+)
+
+// NewHTTP2Request builds a ForwardEchoRequest that calls url using HTTP/2
+// with prior knowledge (no ALPN/Upgrade negotiation), for use against
+// h2c-only backends and tests that need to assert on negotiated stream
+// behavior directly.
+//
+// NOTE: no in-tree echo server forwarder reads Protocol yet (honoring it
+// server-side is tracked as a follow-up), so against a real server this
+// request is currently forwarded using whatever the server infers from Url.
+func NewHTTP2Request(url string) *proto.ForwardEchoRequest {
+	return &proto.ForwardEchoRequest{
+		Url:      url,
+		Count:    1,
+		Protocol: proto.Protocol_HTTP2_PRIOR_KNOWLEDGE,
+	}
+}
+
+// NewWebSocketRequest builds a ForwardEchoRequest that opens a WebSocket
+// connection to url and sends frames, in order, as separate WebSocket
+// frames.
+//
+// NOTE: like NewHTTP2Request, no in-tree forwarder consumes Protocol or
+// WebSocketFrames yet; this is client/proto scaffolding ahead of that
+// server-side work.
+func NewWebSocketRequest(url string, frames [][]byte) *proto.ForwardEchoRequest {
+	return &proto.ForwardEchoRequest{
+		Url:             url,
+		Count:           1,
+		Protocol:        proto.Protocol_WEBSOCKET,
+		WebSocketFrames: frames,
+	}
+}
@@ -0,0 +1,131 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package echo
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"istio.io/istio/pkg/test/application/echo/proto"
+)
+
+// RetryOptions configures ForwardEchoUntil's exponential backoff and
+// ramp-up behavior.
+type RetryOptions struct {
+	// MaxAttempts bounds the number of times ForwardEcho is called. Zero
+	// means unlimited (bounded only by Deadline).
+	MaxAttempts int
+	// Deadline bounds the total time spent retrying. Zero means unlimited
+	// (bounded only by MaxAttempts).
+	Deadline time.Duration
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts.
+	MaxBackoff time.Duration
+	// BackoffMultiplier scales the delay after each failed attempt.
+	BackoffMultiplier float64
+	// CountRamp, if non-nil, is called before each attempt with the attempt
+	// number (starting at 0) to compute the Count to use on the request for
+	// that attempt. If nil, the request's own Count is left unchanged.
+	CountRamp func(attempt int) int32
+}
+
+// DefaultConfigPropagation is a RetryOptions preset tuned for waiting out
+// typical xDS config propagation latency in the control plane.
+var DefaultConfigPropagation = RetryOptions{
+	MaxAttempts:       20,
+	Deadline:          90 * time.Second,
+	InitialBackoff:    200 * time.Millisecond,
+	MaxBackoff:        5 * time.Second,
+	BackoffMultiplier: 1.5,
+}
+
+// ForwardEchoUntil repeatedly calls ForwardEcho with request, applying
+// exponential backoff with jitter between attempts, until predicate returns
+// nil or the retry budget in opts is exhausted. It returns the last
+// response and predicate error (or send error) if the budget runs out.
+func (c *Client) ForwardEchoUntil(
+	ctx context.Context, request *proto.ForwardEchoRequest, predicate func(ParsedResponses) error, opts RetryOptions) (ParsedResponses, error) {
+	backoff := opts.InitialBackoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * time.Second
+	}
+	multiplier := opts.BackoffMultiplier
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+
+	var deadline <-chan time.Time
+	if opts.Deadline > 0 {
+		timer := time.NewTimer(opts.Deadline)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	var lastResponses ParsedResponses
+	var lastErr error
+
+	for attempt := 0; opts.MaxAttempts <= 0 || attempt < opts.MaxAttempts; attempt++ {
+		req := request
+		if opts.CountRamp != nil {
+			ramped := *request
+			ramped.Count = opts.CountRamp(attempt)
+			req = &ramped
+		}
+
+		responses, err := c.ForwardEcho(ctx, req)
+		if err != nil {
+			lastErr = err
+		} else if err := predicate(responses); err != nil {
+			lastResponses, lastErr = responses, err
+		} else {
+			return responses, nil
+		}
+
+		if opts.MaxAttempts > 0 && attempt+1 == opts.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return lastResponses, ctx.Err()
+		case <-deadline:
+			return lastResponses, fmt.Errorf("deadline exceeded after %d attempts, last error: %v", attempt+1, lastErr)
+		case <-time.After(jitter(backoff)):
+		}
+
+		backoff = time.Duration(float64(backoff) * multiplier)
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	return lastResponses, fmt.Errorf("exceeded %d attempts, last error: %v", opts.MaxAttempts, lastErr)
+}
+
+// jitter returns a duration uniformly distributed in [d/2, d], to avoid
+// synchronized retries across many concurrent callers.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
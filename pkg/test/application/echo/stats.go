@@ -0,0 +1,296 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package echo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// minExpectedBucketSize is the minimum expected count per bucket for the
+	// chi-squared approximation to be considered valid, per common practice.
+	minExpectedBucketSize = 5
+
+	// maxIncompleteGammaIterations bounds the series/continued-fraction
+	// expansions below so a pathological input can't spin forever.
+	maxIncompleteGammaIterations = 200
+	incompleteGammaEpsilon       = 1e-12
+)
+
+// clusterKey identifies a distinct destination cluster within a set of
+// responses. Responses are grouped first by Hostname (which pod served the
+// request), falling back to Version when Hostname isn't populated.
+func clusterKey(r *ParsedResponse) string {
+	if r.Hostname != "" {
+		return r.Hostname
+	}
+	return r.Version
+}
+
+// CheckReachedClusters asserts that the observed distribution of responses
+// across clusters (grouped by Hostname, falling back to Version) matches the
+// given expected counts, within tolerancePct percentage points (e.g. 10 for
+// +/-10 points) of each cluster's expected share.
+func (r ParsedResponses) CheckReachedClusters(expected map[string]int, tolerancePct float64) error {
+	if r.Len() == 0 {
+		return fmt.Errorf("no responses received")
+	}
+
+	expectedTotal := 0
+	for _, c := range expected {
+		expectedTotal += c
+	}
+	if expectedTotal == 0 {
+		return fmt.Errorf("expected cluster distribution must have a positive total")
+	}
+
+	observed := make(map[string]int)
+	for _, resp := range r {
+		observed[clusterKey(resp)]++
+	}
+
+	var err error
+	for cluster, expectedCount := range expected {
+		expectedShare := float64(expectedCount) / float64(expectedTotal)
+		actualShare := float64(observed[cluster]) / float64(r.Len())
+		if math.Abs(actualShare-expectedShare) > tolerancePct/100 {
+			err = multierror.Append(err, fmt.Errorf(
+				"cluster %s: expected share %.2f%%, received %.2f%% (tolerance %.2f%%)",
+				cluster, expectedShare*100, actualShare*100, tolerancePct))
+		}
+	}
+	for cluster := range observed {
+		if _, ok := expected[cluster]; !ok {
+			err = multierror.Append(err, fmt.Errorf("unexpected cluster reached: %s", cluster))
+		}
+	}
+	return err
+}
+
+func (r ParsedResponses) CheckReachedClustersOrFail(t testing.TB, expected map[string]int, tolerancePct float64) {
+	if err := r.CheckReachedClusters(expected, tolerancePct); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// CheckChiSquare performs a chi-squared goodness-of-fit test of the observed
+// cluster distribution (grouped by Hostname, falling back to Version)
+// against the given expected probabilities, failing if the p-value is below
+// alpha. Buckets with an expected count below 5 are rejected as
+// insufficient samples, per standard chi-squared guidance.
+func (r ParsedResponses) CheckChiSquare(expected map[string]float64, alpha float64) error {
+	n := r.Len()
+	if n == 0 {
+		return fmt.Errorf("no responses received")
+	}
+
+	observed := make(map[string]int)
+	for _, resp := range r {
+		observed[clusterKey(resp)]++
+	}
+
+	// A response landing on a cluster outside the expected set is a
+	// goodness-of-fit failure on its own, even before any chi-squared math:
+	// without this check, traffic escaping entirely to an unlisted cluster
+	// would never be penalized.
+	for cluster := range observed {
+		if _, ok := expected[cluster]; !ok {
+			return fmt.Errorf("unexpected cluster reached: %s", cluster)
+		}
+	}
+
+	var x2 float64
+	df := 0
+	for bucket, p := range expected {
+		e := float64(n) * p
+		if e < minExpectedBucketSize {
+			return fmt.Errorf("insufficient samples: bucket %q has expected count %.2f, want at least %d; "+
+				"collect more samples or merge buckets", bucket, e, minExpectedBucketSize)
+		}
+		o := float64(observed[bucket])
+		x2 += (o - e) * (o - e) / e
+		df++
+	}
+	df--
+	if df < 1 {
+		return fmt.Errorf("chi-squared test requires at least 2 buckets, got %d", df+1)
+	}
+
+	pValue := chiSquarePValue(x2, df)
+	if pValue < alpha {
+		return fmt.Errorf("chi-squared goodness-of-fit failed: X2=%.4f, df=%d, p=%.4f (alpha=%.4f)",
+			x2, df, pValue, alpha)
+	}
+	return nil
+}
+
+func (r ParsedResponses) CheckChiSquareOrFail(t testing.TB, expected map[string]float64, alpha float64) {
+	if err := r.CheckChiSquare(expected, alpha); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// chiSquarePValue returns the upper-tail p-value P(X2 >= x2) of the
+// chi-squared distribution with df degrees of freedom, computed via the
+// regularized upper incomplete gamma function Q(df/2, x2/2).
+func chiSquarePValue(x2 float64, df int) float64 {
+	if x2 <= 0 {
+		return 1
+	}
+	return upperIncompleteGammaQ(float64(df)/2, x2/2)
+}
+
+// upperIncompleteGammaQ computes the regularized upper incomplete gamma
+// function Q(a, x) = Gamma(a, x) / Gamma(a), using a power series when
+// x < a+1 and a continued fraction expansion otherwise, following the
+// standard approach for evaluating the incomplete gamma function.
+func upperIncompleteGammaQ(a, x float64) float64 {
+	if x < 0 || a <= 0 {
+		return math.NaN()
+	}
+	if x == 0 {
+		return 1
+	}
+	if x < a+1 {
+		return 1 - lowerIncompleteGammaSeries(a, x)
+	}
+	return upperIncompleteGammaContinuedFraction(a, x)
+}
+
+// lowerIncompleteGammaSeries computes the regularized lower incomplete gamma
+// function P(a, x) via its power series representation. Valid for x < a+1.
+func lowerIncompleteGammaSeries(a, x float64) float64 {
+	gln, _ := math.Lgamma(a)
+	term := 1 / a
+	sum := term
+	for n := 1; n < maxIncompleteGammaIterations; n++ {
+		term *= x / (a + float64(n))
+		sum += term
+		if math.Abs(term) < math.Abs(sum)*incompleteGammaEpsilon {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-gln)
+}
+
+// upperIncompleteGammaContinuedFraction computes Q(a, x) via the Lentz
+// continued fraction expansion. Valid for x >= a+1.
+func upperIncompleteGammaContinuedFraction(a, x float64) float64 {
+	gln, _ := math.Lgamma(a)
+
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+
+	for i := 1; i < maxIncompleteGammaIterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < incompleteGammaEpsilon {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-gln) * h
+}
+
+// CheckLatencyPercentile asserts that the p-th nearest-rank percentile of
+// response latency is at or below max. p is given in the range (0, 100].
+// Requires the server to have returned structured EchoReply timing; returns
+// an error if no response carries a non-zero ResponseTime.
+func (r ParsedResponses) CheckLatencyPercentile(p float64, max time.Duration) error {
+	if r.Len() == 0 {
+		return fmt.Errorf("no responses received")
+	}
+
+	durations := make([]time.Duration, 0, r.Len())
+	hasTiming := false
+	for _, resp := range r {
+		if resp.ResponseTime != 0 {
+			hasTiming = true
+		}
+		durations = append(durations, resp.ResponseTime)
+	}
+	if !hasTiming {
+		return fmt.Errorf("no response carries a non-zero ResponseTime; " +
+			"CheckLatencyPercentile requires a server that returns structured EchoReply timing")
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	// Nearest-rank: the smallest 1-based rank k such that k/N >= p/100,
+	// found at index k-1.
+	rank := int(math.Ceil(p/100*float64(len(durations)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(durations) {
+		rank = len(durations) - 1
+	}
+
+	if observed := durations[rank]; observed > max {
+		return fmt.Errorf("p%g latency %s exceeds max %s", p, observed, max)
+	}
+	return nil
+}
+
+func (r ParsedResponses) CheckLatencyPercentileOrFail(t testing.TB, p float64, max time.Duration) {
+	if err := r.CheckLatencyPercentile(p, max); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// CheckAllVersionsHit asserts that the response set contains at least one
+// response from every version in expected.
+func (r ParsedResponses) CheckAllVersionsHit(expected []string) error {
+	if r.Len() == 0 {
+		return fmt.Errorf("no responses received")
+	}
+
+	seen := make(map[string]bool)
+	for _, resp := range r {
+		seen[resp.Version] = true
+	}
+
+	var err error
+	for _, version := range expected {
+		if !seen[version] {
+			err = multierror.Append(err, fmt.Errorf("version %s was never hit", version))
+		}
+	}
+	return err
+}
+
+func (r ParsedResponses) CheckAllVersionsHitOrFail(t testing.TB, expected []string) {
+	if err := r.CheckAllVersionsHit(expected); err != nil {
+		t.Fatal(err)
+	}
+}
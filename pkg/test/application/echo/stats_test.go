@@ -0,0 +1,70 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package echo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChiSquarePValue(t *testing.T) {
+	// Known critical values from standard chi-squared tables: X2 at a given
+	// (df, alpha) should map back to a p-value of approximately alpha.
+	cases := []struct {
+		df      int
+		x2      float64
+		wantP   float64
+		wantTol float64
+	}{
+		{df: 1, x2: 3.841, wantP: 0.05, wantTol: 0.001},
+		{df: 1, x2: 6.635, wantP: 0.01, wantTol: 0.001},
+		{df: 3, x2: 7.815, wantP: 0.05, wantTol: 0.001},
+		{df: 5, x2: 11.07, wantP: 0.05, wantTol: 0.001},
+		{df: 10, x2: 18.31, wantP: 0.05, wantTol: 0.001},
+	}
+
+	for _, c := range cases {
+		got := chiSquarePValue(c.x2, c.df)
+		if math.Abs(got-c.wantP) > c.wantTol {
+			t.Errorf("chiSquarePValue(%v, %d) = %v, want %v (+/- %v)", c.x2, c.df, got, c.wantP, c.wantTol)
+		}
+	}
+}
+
+func TestChiSquarePValueNoDifference(t *testing.T) {
+	if p := chiSquarePValue(0, 3); p != 1 {
+		t.Errorf("chiSquarePValue(0, 3) = %v, want 1", p)
+	}
+}
+
+func TestCheckChiSquareRejectsUnexpectedCluster(t *testing.T) {
+	responses := ParsedResponses{
+		{Hostname: "v1"}, {Hostname: "v1"}, {Hostname: "v1"},
+		{Hostname: "v1"}, {Hostname: "v1"}, {Hostname: "rogue"},
+	}
+
+	err := responses.CheckChiSquare(map[string]float64{"v1": 1.0}, 0.05)
+	if err == nil {
+		t.Fatal("expected CheckChiSquare to reject traffic reaching a cluster outside the expected set")
+	}
+}
+
+func TestCheckLatencyPercentileRequiresTiming(t *testing.T) {
+	responses := ParsedResponses{{}, {}, {}}
+
+	if err := responses.CheckLatencyPercentile(99, 0); err == nil {
+		t.Fatal("expected CheckLatencyPercentile to fail when no response carries timing")
+	}
+}